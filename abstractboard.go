@@ -3,13 +3,33 @@ package libaduk
 import (
     "fmt"
     "log"
+    "math/bits"
 )
 
 // Represents a Go board data structure
 type AbstractBoard struct {
     BoardSize uint8
+    komi float32
     data []BoardStatus
     undoStack []*Move
+
+    // Incremental string/liberty tracking (union-find over board points).
+    // parent[i] == -1 means point i is empty and not part of any string.
+    // Only a string's root carries a meaningful liberties bitmap; next
+    // forms a circular linked list over a string's stones so that all of
+    // them can be enumerated (e.g. on capture) without scanning the board.
+    parent []int32
+    rank []uint8
+    next []int32
+    liberties [][]uint64
+    shardCount int
+
+    // Positional/situational superko detection via Zobrist hashing
+    koRule KoRule
+    zobrist [2][]uint64
+    zobristSide [2]uint64
+    hash uint64
+    seen map[uint64]struct{}
 }
 
 // Creates new Go Board
@@ -18,11 +38,27 @@ func NewBoard(boardSize uint8) (*AbstractBoard, error) {
         return nil, fmt.Errorf("Boardsize can not be less than 1!")
     }
 
-    return &AbstractBoard {
+    board := &AbstractBoard {
         boardSize,
+        0,
         make([]BoardStatus, boardSize * boardSize),
         make([]*Move, 0),
-    }, nil
+        nil,
+        nil,
+        nil,
+        nil,
+        0,
+        KoNone,
+        [2][]uint64 { },
+        [2]uint64 { },
+        0,
+        nil,
+    }
+
+    board.resetStrings()
+    board.resetZobrist()
+
+    return board, nil
 }
 
 // Returns a string representation of the current board status
@@ -46,12 +82,24 @@ func (board *AbstractBoard) ToString() string {
     return result
 }
 
+// Returns the board's komi
+func (board *AbstractBoard) Komi() float32 {
+    return board.komi
+}
+
+// Sets the board's komi
+func (board *AbstractBoard) SetKomi(komi float32) {
+    board.komi = komi
+}
+
 // Clears the board
 func (board *AbstractBoard) Clear() {
     for i := 0; i < len(board.data); i++ {
         board.data[i] = EMPTY
     }
     board.undoStack = []*Move { }
+    board.resetStrings()
+    board.resetZobrist()
 }
 
 // Returns the Top Move of the Undostack
@@ -59,11 +107,68 @@ func (board *AbstractBoard) UndostackTopMove() *Move {
     return board.undoStack[len(board.undoStack) - 1]
 }
 
+// Returns the moves played so far, in order
+func (board *AbstractBoard) Moves() []*Move {
+    moves := make([]*Move, len(board.undoStack))
+    copy(moves, board.undoStack)
+
+    return moves
+}
+
 // Removes last Move from Undostack
 func (board *AbstractBoard) UndostackPop() (move *Move) {
     if len(board.undoStack) > 0 {
         move = board.undoStack[len(board.undoStack) - 1]
         board.undoStack = board.undoStack[:len(board.undoStack) - 1]
+
+        switch move.Color {
+        case SETUP:
+            for i := 0; i < len(move.SetupBlack); i++ {
+                board.setStatus(move.SetupBlack[i].X, move.SetupBlack[i].Y, EMPTY)
+            }
+            for i := 0; i < len(move.SetupWhite); i++ {
+                board.setStatus(move.SetupWhite[i].X, move.SetupWhite[i].Y, EMPTY)
+            }
+            board.rebuildStrings()
+
+        case DEAD:
+            // SetupBlack/SetupWhite carry the stones DeadStones removed,
+            // grouped by the color they need restoring to
+            for i := 0; i < len(move.SetupBlack); i++ {
+                board.setStatus(move.SetupBlack[i].X, move.SetupBlack[i].Y, BLACK)
+            }
+            for i := 0; i < len(move.SetupWhite); i++ {
+                board.setStatus(move.SetupWhite[i].X, move.SetupWhite[i].Y, WHITE)
+            }
+            board.rebuildStrings()
+
+        case PASS:
+            // Nothing on the board to revert
+
+        default:
+            // checkKo registers superko positions under a hash mixed with
+            // the side to move next, so the delete key has to be computed
+            // the same way rather than using board.hash directly
+            if board.koRule == KoPositionalSuperko || board.koRule == KoSituationalSuperko {
+                hash := board.hash
+                if board.koRule == KoSituationalSuperko {
+                    hash ^= board.zobristSide[board.zobristColorIndex(board.invertColor(move.Color))]
+                }
+                delete(board.seen, hash)
+            }
+
+            board.setStatus(move.X, move.Y, EMPTY)
+            for i := 0; i < len(move.Captures); i++ {
+                board.setStatus(move.Captures[i].X, move.Captures[i].Y, board.invertColor(move.Color))
+            }
+
+            // Undo is rare compared to Play, so rebuilding the incremental
+            // string/liberty state from the board is cheap enough here and
+            // avoids having to carry a full union-find snapshot per Move.
+            board.rebuildStrings()
+        }
+
+        board.hash = move.PreHash
     }
 
     return
@@ -78,7 +183,7 @@ func (board *AbstractBoard) UndostackPush(move *Move) {
 
 // Adds a Pass to the Undostack
 func (board *AbstractBoard) UndostackPushPass() {
-    board.UndostackPush(&Move { 255, 255, PASS, nil })
+    board.UndostackPush(&Move { 255, 255, PASS, nil, board.hash, nil, nil })
 }
 
 // Play move on board
@@ -100,148 +205,221 @@ func (board *AbstractBoard) Play(x uint8, y uint8, color BoardStatus) (error) {
         return fmt.Errorf("Position already occupied!")
     }
 
-    // Check if move is legal and get captures
-    captures, err := board.legal(x, y, color)
-    if err != nil {
-        return err
+    preHash := board.hash
+
+    idx := board.posIndex(x, y)
+    neighbours := board.getNeighbours(x, y)
+
+    // Place the stone as a singleton string whose liberties are its empty neighbours
+    board.setStatus(x, y, color)
+    board.parent[idx] = int32(idx)
+    board.rank[idx] = 0
+    board.next[idx] = int32(idx)
+    board.liberties[idx] = make([]uint64, board.shardCount)
+    for i := 0; i < len(neighbours); i++ {
+        if board.getStatus(neighbours[i].X, neighbours[i].Y) == EMPTY {
+            board.setLiberty(idx, board.posIndex(neighbours[i].X, neighbours[i].Y))
+        }
     }
 
-    // Remove captures
-    for i := 0; i < len(captures); i++ {
-        board.setStatus(captures[i].X, captures[i].Y, EMPTY)
+    captures := []Position { }
+    enemy := board.invertColor(color)
+
+    for i := 0; i < len(neighbours); i++ {
+        nIdx := board.posIndex(neighbours[i].X, neighbours[i].Y)
+
+        switch board.getStatus(neighbours[i].X, neighbours[i].Y) {
+        case color:
+            // The played point itself is no longer a liberty of the merged string
+            board.clearLiberty(board.union(idx, nIdx), idx)
+        case enemy:
+            root := board.find(nIdx)
+            board.clearLiberty(root, idx)
+            if board.libertyCount(root) == 0 {
+                captures = append(captures, board.removeString(root)...)
+            }
+        }
+    }
+
+    // Check if the played move has no liberties and therefore is a suicide
+    ownRoot := board.find(idx)
+    if board.libertyCount(ownRoot) == 0 {
+        // A suicide captured nothing, so only the played stone is reverted;
+        // any friendly strings it joined stay on the board
+        board.undoPlacement(x, y, color, nil)
+        board.hash = preHash
+        return fmt.Errorf("Invalid move (Suicide not allowed)!")
+    }
+
+    // Check for ko/superko repetition, rolling the move back if found
+    if board.koRule != KoNone {
+        if err := board.checkKo(color, captures); err != nil {
+            board.undoPlacement(x, y, color, captures)
+            board.hash = preHash
+            return err
+        }
     }
 
-    // Add them to undostack
-    board.UndostackPush(&Move { x, y, color, captures })
+    board.UndostackPush(&Move { x, y, color, captures, preHash, nil, nil })
 
     return nil
 }
 
-// Checks if move is legal and returns captured stones if necessary
-func (board *AbstractBoard) legal(x uint8, y uint8, color BoardStatus) (captures []Position, err error) {
-    captures = []Position { }
-    neighbours := board.getNeighbours(x, y)
+// undoPlacement reverts a tentatively played stone and restores its
+// captures, without touching the Undostack. Used to roll back a move that
+// turned out to be a suicide or to violate the active ko rule.
+func (board *AbstractBoard) undoPlacement(x uint8, y uint8, color BoardStatus, captures []Position) {
+    board.setStatus(x, y, EMPTY)
+    for i := 0; i < len(captures); i++ {
+        board.setStatus(captures[i].X, captures[i].Y, board.invertColor(color))
+    }
+    board.rebuildStrings()
+}
 
-    log.SetPrefix("legal ")
-    log.Printf("Neighbours for Playmove (X: %d, Y: %d) are %+v", x, y, neighbours)
+// Returns the root of the string idx belongs to, with path compression
+func (board *AbstractBoard) find(idx int) int {
+    for board.parent[idx] != int32(idx) {
+        board.parent[idx] = board.parent[board.parent[idx]]
+        idx = int(board.parent[idx])
+    }
 
-    // Check if we capture neighbouring stones
-    for i := 0; i < len(neighbours); i++ {
-        // Is neighbour from another color?
-        if board.getStatus(neighbours[i].X, neighbours[i].Y) == board.invertColor(color) {
-            log.SetPrefix("legal ")
-            log.Printf("Neighbour of Playmove (X: %d, Y: %d) at (X: %d, Y: %d) is %v. Get its No liberties...",
-                x, y, neighbours[i].X, neighbours[i].Y, board.invertColor(color))
-
-            // Get enemy stones with no liberties left
-            noLibertyStones := board.getNoLibertyStones(neighbours[i].X, neighbours[i].Y, Position { x, y })
-            for j := 0; j < len(noLibertyStones); j++ {
-                captures = append(captures, noLibertyStones[j])
-            }
-        }
+    return idx
+}
+
+// Merges the strings containing a and b, OR-merging their liberty bitmaps
+func (board *AbstractBoard) union(a int, b int) int {
+    rootA := board.find(a)
+    rootB := board.find(b)
+
+    if rootA == rootB {
+        return rootA
     }
 
-    board.setStatus(x, y, color)
+    if board.rank[rootA] < board.rank[rootB] {
+        rootA, rootB = rootB, rootA
+    }
 
-    // TODO: Delete Duplicates necessary????
-    if len(captures) > 0 {
-        return
+    board.parent[rootB] = int32(rootA)
+    if board.rank[rootA] == board.rank[rootB] {
+        board.rank[rootA]++
     }
 
-    // Check if the played move has no liberties and therefore is a suicide
-    log.SetPrefix("legal ")
-    log.Printf("Check if Playmove (%d, %d) is a suicide.", x, y)
-    selfNoLiberties := board.getNoLibertyStones(x, y, Position { })
-    if len(selfNoLiberties) > 0 {
-        // Take move back
-        board.setStatus(x, y, EMPTY)
-        err = fmt.Errorf("Invalid move (Suicide not allowed)!")
+    for s := 0; s < board.shardCount; s++ {
+        board.liberties[rootA][s] |= board.liberties[rootB][s]
     }
+    board.liberties[rootB] = nil
 
-    log.SetPrefix("")
-    return
+    // Splice the two stones' circular string rings together
+    board.next[a], board.next[b] = board.next[b], board.next[a]
+
+    return rootA
 }
 
-// Get all stones with no liberties left on given position
-func (board *AbstractBoard) getNoLibertyStones(x uint8, y uint8, orgPosition Position) (noLibertyStones []Position) {
-    log.SetPrefix("getNoLibertyStones ")
-    log.Printf("Get no liberty stones for (%d, %d)", x, y)
-
-    noLibertyStones = []Position { }
-    newlyFoundStones := []Position { Position { x, y } }
-    foundNew := true
-    var groupStones []Position = nil
-
-    // Search until no new stones are found
-    for foundNew == true {
-        foundNew = false
-        groupStones = []Position { }
-
-        for i := 0; i < len(newlyFoundStones); i++ {
-            x1 := newlyFoundStones[i].X
-            y1 := newlyFoundStones[i].Y
-            neighbours := board.getNeighbours(x1, y1)
-
-            // Check liberties of stone x1, y1 by checking the neighbours
-            for j := 0; j < len(neighbours); j++ {
-                nbX := neighbours[j].X
-                nbY := neighbours[j].Y
-
-                // Has x1, y1 a free liberty?
-                if board.getStatus(nbX, nbY) == EMPTY && !neighbours[j].isSamePosition(orgPosition) {
-                    log.SetPrefix("getNoLibertyStones ")
-                    log.Printf("Neighbour (%d, %d) is empty and not (%d, %d) so (%d, %d) has at least liberty",
-                        nbX, nbY, orgPosition.X, orgPosition.Y, x, y)
-                    return noLibertyStones[:0]
-                } else {
-                    // Is the neighbour of x1, y1 the same color? Then we have a group here
-                    if board.getStatus(x1, y1) == board.getStatus(nbX, nbY) {
-                        foundNewHere := true
-                        groupStone := Position { nbX, nbY }
-
-                        log.SetPrefix("getNoLibertyStones ")
-                        log.Printf("Found group stone for (%d, %d) at %+v", x1, y1, groupStone)
-
-                        // Check if found stone is already in our group list
-                        for k := 0; k < len(groupStones); k++ {
-                            if groupStones[k].isSamePosition(groupStone) {
-                                foundNewHere = false
-                                break
-                            }
-                        }
-
-                        // Check if found stone is already in result set list
-                        if foundNewHere {
-                            for k := 0; k < len(noLibertyStones); k++ {
-                                if noLibertyStones[k].isSamePosition(groupStone) {
-                                    foundNewHere = false
-                                    break
-                                }
-                            }
-                        }
-
-                        // If groupStone is not known yet, add it
-                        if foundNewHere {
-                            groupStones = append(groupStones, groupStone)
-                            foundNew = true
-                        }
-                    }
-                }
+// Removes every stone of the string rooted at root from the board, freeing
+// their points back into the liberty bitmaps of any neighbouring strings
+func (board *AbstractBoard) removeString(root int) []Position {
+    removed := []Position { }
+
+    for cur, start := root, root; ; {
+        removed = append(removed, board.indexToPosition(cur))
+        cur = int(board.next[cur])
+        if cur == start {
+            break
+        }
+    }
+
+    for i := 0; i < len(removed); i++ {
+        idx := board.posIndex(removed[i].X, removed[i].Y)
+        board.setStatus(removed[i].X, removed[i].Y, EMPTY)
+        board.parent[idx] = -1
+        board.rank[idx] = 0
+        board.next[idx] = int32(idx)
+        board.liberties[idx] = nil
+    }
+
+    for i := 0; i < len(removed); i++ {
+        neighbours := board.getNeighbours(removed[i].X, removed[i].Y)
+        for j := 0; j < len(neighbours); j++ {
+            if board.getStatus(neighbours[j].X, neighbours[j].Y) != EMPTY {
+                nRoot := board.find(board.posIndex(neighbours[j].X, neighbours[j].Y))
+                board.setLiberty(nRoot, board.posIndex(removed[i].X, removed[i].Y))
             }
         }
+    }
+
+    return removed
+}
+
+// Rebuilds the union-find string/liberty state from the current board
+// contents. Used after UndostackPop, which restores board.data directly.
+func (board *AbstractBoard) rebuildStrings() {
+    board.resetStrings()
 
-        // Add newly found stones to the resultset
-        noLibertyStones = append(noLibertyStones, newlyFoundStones...)
+    for idx := 0; idx < len(board.data); idx++ {
+        if board.data[idx] == EMPTY {
+            continue
+        }
 
-        // Now check the found group stones
-        newlyFoundStones = groupStones
+        board.parent[idx] = int32(idx)
+        board.rank[idx] = 0
+        board.next[idx] = int32(idx)
+        board.liberties[idx] = make([]uint64, board.shardCount)
     }
 
-    log.SetPrefix("getNoLibertyStones ")
-    log.Printf("Found these stones with no liberties: %+v", noLibertyStones)
-    log.SetPrefix("")
+    for idx := 0; idx < len(board.data); idx++ {
+        if board.data[idx] == EMPTY {
+            continue
+        }
 
-    return
+        pos := board.indexToPosition(idx)
+        neighbours := board.getNeighbours(pos.X, pos.Y)
+
+        for i := 0; i < len(neighbours); i++ {
+            nIdx := board.posIndex(neighbours[i].X, neighbours[i].Y)
+
+            if board.getStatus(neighbours[i].X, neighbours[i].Y) == EMPTY {
+                board.setLiberty(board.find(idx), nIdx)
+            } else if board.data[nIdx] == board.data[idx] {
+                board.union(idx, nIdx)
+            }
+        }
+    }
+}
+
+// (Re)initializes the union-find bookkeeping for an all-empty board
+func (board *AbstractBoard) resetStrings() {
+    size := len(board.data)
+    board.shardCount = (size + 63) / 64
+
+    board.parent = make([]int32, size)
+    board.rank = make([]uint8, size)
+    board.next = make([]int32, size)
+    board.liberties = make([][]uint64, size)
+
+    for i := 0; i < size; i++ {
+        board.parent[i] = -1
+        board.next[i] = int32(i)
+    }
+}
+
+// Sets the liberty bit for point idx on the string rooted at root
+func (board *AbstractBoard) setLiberty(root int, idx int) {
+    board.liberties[root][idx / 64] |= 1 << uint(idx % 64)
+}
+
+// Clears the liberty bit for point idx on the string rooted at root
+func (board *AbstractBoard) clearLiberty(root int, idx int) {
+    board.liberties[root][idx / 64] &^= 1 << uint(idx % 64)
+}
+
+// Returns the number of liberties left for the string rooted at root
+func (board *AbstractBoard) libertyCount(root int) int {
+    count := 0
+    for s := 0; s < board.shardCount; s++ {
+        count += bits.OnesCount64(board.liberties[root][s])
+    }
+
+    return count
 }
 
 // Returns the neighbour array positions for a given point
@@ -265,12 +443,31 @@ func (board *AbstractBoard) getNeighbours(x uint8, y uint8) (neighbourIndexes []
     return
 }
 
+func (board *AbstractBoard) posIndex(x uint8, y uint8) int {
+    return int(board.BoardSize) * int(x) + int(y)
+}
+
+func (board *AbstractBoard) indexToPosition(idx int) Position {
+    return Position { uint8(idx / int(board.BoardSize)), uint8(idx % int(board.BoardSize)) }
+}
+
 func (board *AbstractBoard) getStatus(x uint8, y uint8) BoardStatus {
-    return board.data[board.BoardSize * x + y]
+    return board.data[board.posIndex(x, y)]
 }
 
 func (board *AbstractBoard) setStatus(x uint8, y uint8, status BoardStatus) {
-    board.data[board.BoardSize * x + y] = status
+    idx := board.posIndex(x, y)
+
+    old := board.data[idx]
+    if old == BLACK || old == WHITE {
+        board.hash ^= board.zobrist[board.zobristColorIndex(old)][idx]
+    }
+
+    board.data[idx] = status
+
+    if status == BLACK || status == WHITE {
+        board.hash ^= board.zobrist[board.zobristColorIndex(status)][idx]
+    }
 }
 
 // Inverts Black to White or White to Black
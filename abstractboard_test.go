@@ -0,0 +1,86 @@
+package libaduk
+
+import "testing"
+
+func TestPlayCapturesEnclosedString(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+
+    // Surround a single white stone at (4,4)
+    plays := []struct {
+        x, y  uint8
+        color BoardStatus
+    } {
+        { 4, 3, BLACK },
+        { 3, 4, BLACK },
+        { 5, 4, BLACK },
+        { 4, 4, WHITE },
+        { 4, 5, BLACK },
+    }
+
+    for _, p := range plays {
+        if err := board.Play(p.x, p.y, p.color); err != nil {
+            t.Fatalf("Play(%d, %d, %v): %v", p.x, p.y, p.color, err)
+        }
+    }
+
+    if status := board.getStatus(4, 4); status != EMPTY {
+        t.Fatalf("expected (4,4) to be captured, got %v", status)
+    }
+
+    last := board.UndostackTopMove()
+    if len(last.Captures) != 1 || last.Captures[0] != (Position { 4, 4 }) {
+        t.Fatalf("expected the last move to record the capture at (4,4), got %+v", last.Captures)
+    }
+}
+
+func TestPlayRejectsSuicide(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+
+    for _, p := range []Position { { 4, 3 }, { 3, 4 }, { 5, 4 }, { 4, 5 } } {
+        if err := board.Play(p.X, p.Y, BLACK); err != nil {
+            t.Fatalf("Play(%d, %d, BLACK): %v", p.X, p.Y, err)
+        }
+    }
+
+    if err := board.Play(4, 4, WHITE); err == nil {
+        t.Fatal("expected suicide move to be rejected")
+    }
+
+    if status := board.getStatus(4, 4); status != EMPTY {
+        t.Fatalf("expected (4,4) to remain empty after a rejected suicide, got %v", status)
+    }
+}
+
+func TestUndostackPopRestoresCapturedStones(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+
+    for _, p := range []Position { { 4, 3 }, { 3, 4 }, { 5, 4 } } {
+        if err := board.Play(p.X, p.Y, BLACK); err != nil {
+            t.Fatalf("Play(%d, %d, BLACK): %v", p.X, p.Y, err)
+        }
+    }
+    if err := board.Play(4, 4, WHITE); err != nil {
+        t.Fatalf("Play(4, 4, WHITE): %v", err)
+    }
+    if err := board.Play(4, 5, BLACK); err != nil {
+        t.Fatalf("Play(4, 5, BLACK): %v", err)
+    }
+
+    board.UndostackPop()
+
+    if status := board.getStatus(4, 4); status != WHITE {
+        t.Fatalf("expected the captured white stone to be restored, got %v", status)
+    }
+    if err := board.Play(3, 3, BLACK); err != nil {
+        t.Fatalf("Play(3, 3, BLACK) after undo: %v", err)
+    }
+}
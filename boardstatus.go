@@ -0,0 +1,15 @@
+package libaduk
+
+// Represents the contents of a board point, or a special marker on a Move
+type BoardStatus int8
+
+const (
+    EMPTY BoardStatus = iota
+    BLACK
+    WHITE
+    PASS
+    SETUP
+    // Marks a Move recording stones removed by DeadStones, so UndostackPop
+    // can restore them
+    DEAD
+)
@@ -0,0 +1,39 @@
+package gtp
+
+import (
+    "math/rand"
+
+    aduk "github.com/arjenketelaar/libaduk"
+)
+
+// Engine picks (and plays) the next move for color on board and returns
+// the GTP vertex it chose, or "pass"
+type Engine interface {
+    GenMove(board *aduk.AbstractBoard, color aduk.BoardStatus) (string, error)
+}
+
+// RandomEngine plays a uniformly random legal move. It exists as a
+// reference Engine so the GTP transport can be tested without a real AI backend.
+type RandomEngine struct { }
+
+// Creates a new RandomEngine
+func NewRandomEngine() *RandomEngine {
+    return &RandomEngine { }
+}
+
+// Plays a uniformly random legal move for color, passing if none is found
+func (e *RandomEngine) GenMove(board *aduk.AbstractBoard, color aduk.BoardStatus) (string, error) {
+    size := int(board.BoardSize)
+
+    for _, idx := range rand.Perm(size * size) {
+        x := uint8(idx / size)
+        y := uint8(idx % size)
+
+        if err := board.Play(x, y, color); err == nil {
+            return FormatVertex(x, y, board.BoardSize)
+        }
+    }
+
+    board.UndostackPushPass()
+    return "pass", nil
+}
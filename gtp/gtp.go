@@ -0,0 +1,254 @@
+// Package gtp implements a GTP (Go Text Protocol) version 2 server over
+// stdio, so libaduk boards can be driven by GNU Go, KataGo, Sabaki, or any
+// other GTP-aware GUI.
+package gtp
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+
+    aduk "github.com/arjenketelaar/libaduk"
+)
+
+// Commands supported by Serve, used to answer known_command/list_commands
+var commands = []string {
+    "protocol_version",
+    "name",
+    "version",
+    "known_command",
+    "list_commands",
+    "quit",
+    "boardsize",
+    "clear_board",
+    "komi",
+    "play",
+    "genmove",
+    "undo",
+    "showboard",
+    "final_score",
+}
+
+// Holds the mutable state of a single GTP session
+type session struct {
+    board *aduk.AbstractBoard
+    komi float32
+    engine Engine
+    quit bool
+}
+
+// Serve reads GTP commands from in and writes responses to out until
+// "quit" is received or in is exhausted, driving board (and, via genmove,
+// engine)
+func Serve(board *aduk.AbstractBoard, in io.Reader, out io.Writer, engine Engine) error {
+    sess := &session { board: board, engine: engine }
+    scanner := bufio.NewScanner(in)
+
+    for !sess.quit && scanner.Scan() {
+        id, command, args := parseLine(scanner.Text())
+        if command == "" {
+            continue
+        }
+
+        result, err := sess.dispatch(command, args)
+        writeResponse(out, id, err, result)
+    }
+
+    return scanner.Err()
+}
+
+// parseLine strips comments and splits a GTP input line into its optional
+// numeric id, the command name, and its arguments
+func parseLine(line string) (id string, command string, args []string) {
+    if i := strings.IndexByte(line, '#'); i >= 0 {
+        line = line[:i]
+    }
+
+    fields := strings.Fields(line)
+    if len(fields) == 0 {
+        return
+    }
+
+    if _, err := strconv.Atoi(fields[0]); err == nil {
+        id = fields[0]
+        fields = fields[1:]
+    }
+    if len(fields) == 0 {
+        return
+    }
+
+    return id, fields[0], fields[1:]
+}
+
+// writeResponse writes a single framed GTP response, terminated by a blank line
+func writeResponse(out io.Writer, id string, err error, result string) {
+    status := "="
+    if err != nil {
+        status = "?"
+        result = err.Error()
+    }
+
+    fmt.Fprintf(out, "%s%s %s\n\n", status, id, result)
+}
+
+func (sess *session) dispatch(command string, args []string) (string, error) {
+    switch command {
+    case "protocol_version":
+        return "2", nil
+
+    case "name":
+        return "libaduk", nil
+
+    case "version":
+        return "0.1", nil
+
+    case "known_command":
+        if len(args) != 1 {
+            return "", fmt.Errorf("known_command requires a command name!")
+        }
+        return strconv.FormatBool(knownCommand(args[0])), nil
+
+    case "list_commands":
+        return strings.Join(commands, "\n"), nil
+
+    case "quit":
+        sess.quit = true
+        return "", nil
+
+    case "boardsize":
+        return sess.boardsize(args)
+
+    case "clear_board":
+        sess.board.Clear()
+        return "", nil
+
+    case "komi":
+        return sess.setKomi(args)
+
+    case "play":
+        return sess.play(args)
+
+    case "genmove":
+        return sess.genmove(args)
+
+    case "undo":
+        if sess.board.UndostackPop() == nil {
+            return "", fmt.Errorf("Cannot undo, board is empty!")
+        }
+        return "", nil
+
+    case "showboard":
+        return sess.board.ToString(), nil
+
+    case "final_score":
+        return sess.finalScore(), nil
+    }
+
+    return "", fmt.Errorf("Unknown command %q!", command)
+}
+
+func knownCommand(command string) bool {
+    for _, c := range commands {
+        if c == command {
+            return true
+        }
+    }
+    return false
+}
+
+func (sess *session) boardsize(args []string) (string, error) {
+    if len(args) != 1 {
+        return "", fmt.Errorf("boardsize requires a single size argument!")
+    }
+
+    size, err := strconv.Atoi(args[0])
+    if err != nil {
+        return "", fmt.Errorf("Invalid boardsize %q!", args[0])
+    }
+
+    board, err := aduk.NewBoard(uint8(size))
+    if err != nil {
+        return "", err
+    }
+
+    sess.board = board
+    return "", nil
+}
+
+func (sess *session) setKomi(args []string) (string, error) {
+    if len(args) != 1 {
+        return "", fmt.Errorf("komi requires a single value argument!")
+    }
+
+    komi, err := strconv.ParseFloat(args[0], 32)
+    if err != nil {
+        return "", fmt.Errorf("Invalid komi %q!", args[0])
+    }
+
+    sess.komi = float32(komi)
+    return "", nil
+}
+
+func (sess *session) play(args []string) (string, error) {
+    if len(args) != 2 {
+        return "", fmt.Errorf("play requires a color and a vertex!")
+    }
+
+    color, err := parseColor(args[0])
+    if err != nil {
+        return "", err
+    }
+
+    x, y, pass, err := ParseVertex(args[1], sess.board.BoardSize)
+    if err != nil {
+        return "", err
+    }
+
+    if pass {
+        sess.board.UndostackPushPass()
+        return "", nil
+    }
+
+    return "", sess.board.Play(x, y, color)
+}
+
+func (sess *session) genmove(args []string) (string, error) {
+    if len(args) != 1 {
+        return "", fmt.Errorf("genmove requires a color!")
+    }
+
+    color, err := parseColor(args[0])
+    if err != nil {
+        return "", err
+    }
+
+    return sess.engine.GenMove(sess.board, color)
+}
+
+// finalScore reports the result of area (Chinese-rules) scoring, as
+// "B+<margin>", "W+<margin>" or "0" for a draw
+func (sess *session) finalScore() string {
+    score := sess.board.Score(sess.komi, aduk.ChineseScoring)
+
+    switch score.Winner {
+    case aduk.BLACK:
+        return fmt.Sprintf("B+%.1f", score.Margin)
+    case aduk.WHITE:
+        return fmt.Sprintf("W+%.1f", score.Margin)
+    default:
+        return "0"
+    }
+}
+
+func parseColor(value string) (aduk.BoardStatus, error) {
+    switch strings.ToLower(value) {
+    case "b", "black":
+        return aduk.BLACK, nil
+    case "w", "white":
+        return aduk.WHITE, nil
+    }
+
+    return aduk.EMPTY, fmt.Errorf("Unknown color %q!", value)
+}
@@ -0,0 +1,48 @@
+package gtp
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// Standard GTP column letters: A-Z skipping I, to avoid confusion with 1
+const gtpColumns = "ABCDEFGHJKLMNOPQRSTUVWXYZ"
+
+// FormatVertex formats board coordinates as a GTP vertex such as "Q16"
+func FormatVertex(x uint8, y uint8, boardSize uint8) (string, error) {
+    if int(x) >= len(gtpColumns) {
+        return "", fmt.Errorf("X coordinate %d is outside the supported GTP column range!", x)
+    }
+
+    row := int(boardSize) - int(y)
+    return fmt.Sprintf("%c%d", gtpColumns[x], row), nil
+}
+
+// ParseVertex parses a GTP vertex ("Q16", or "pass") into board coordinates
+func ParseVertex(vertex string, boardSize uint8) (x uint8, y uint8, pass bool, err error) {
+    v := strings.ToUpper(strings.TrimSpace(vertex))
+    if v == "PASS" {
+        return 0, 0, true, nil
+    }
+    if len(v) < 2 {
+        return 0, 0, false, fmt.Errorf("Invalid vertex %q!", vertex)
+    }
+
+    col := strings.IndexByte(gtpColumns, v[0])
+    if col < 0 {
+        return 0, 0, false, fmt.Errorf("Invalid GTP column in vertex %q!", vertex)
+    }
+
+    row, err := strconv.Atoi(v[1:])
+    if err != nil {
+        return 0, 0, false, fmt.Errorf("Invalid GTP row in vertex %q!", vertex)
+    }
+
+    yi := int(boardSize) - row
+    if yi < 0 || yi >= int(boardSize) || col >= int(boardSize) {
+        return 0, 0, false, fmt.Errorf("Vertex %q is outside the board!", vertex)
+    }
+
+    return uint8(col), uint8(yi), false, nil
+}
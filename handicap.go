@@ -0,0 +1,99 @@
+package libaduk
+
+import "fmt"
+
+// Star-point layout used to build the standard fixed handicap patterns,
+// as 0-indexed distances from the edge/center of the board
+var handicapLayouts = map[uint8]struct{ edge uint8; mid uint8; center uint8 } {
+    9:  { 2, 4, 4 },
+    13: { 3, 6, 6 },
+    19: { 3, 9, 9 },
+}
+
+// Seeds the board with the standard 2-9 stone handicap pattern for 9x9,
+// 13x13 and 19x19 boards. Returns an error for any other board size or
+// stone count.
+func (board *AbstractBoard) PlaceHandicap(stones int) error {
+    if stones < 2 || stones > 9 {
+        return fmt.Errorf("Handicap must be between 2 and 9 stones, got %d!", stones)
+    }
+
+    layout, ok := handicapLayouts[board.BoardSize]
+    if !ok {
+        return fmt.Errorf("No standard handicap pattern for board size %d!", board.BoardSize)
+    }
+
+    low := layout.edge
+    high := board.BoardSize - 1 - layout.edge
+    mid := layout.mid
+    center := Position { layout.center, layout.center }
+
+    corners := []Position { { high, low }, { low, high }, { high, high }, { low, low } }
+    verticalEdges := []Position { { low, mid }, { high, mid } }
+    horizontalEdges := []Position { { mid, low }, { mid, high } }
+
+    count := stones
+    if count > 4 {
+        count = 4
+    }
+    points := append([]Position { }, corners[:count]...)
+
+    switch stones {
+    case 5:
+        points = append(points, center)
+    case 6:
+        points = append(points, verticalEdges...)
+    case 7:
+        points = append(points, verticalEdges...)
+        points = append(points, center)
+    case 8:
+        points = append(points, verticalEdges...)
+        points = append(points, horizontalEdges...)
+    case 9:
+        points = append(points, verticalEdges...)
+        points = append(points, horizontalEdges...)
+        points = append(points, center)
+    }
+
+    return board.AddSetupStones(points, nil)
+}
+
+// Places free setup stones for black and white, bypassing the legality
+// and suicide checks that Play applies. Pushes a single SETUP entry onto the
+// Undostack so UndostackPop can clear exactly these stones again.
+func (board *AbstractBoard) AddSetupStones(black []Position, white []Position) error {
+    placed := make(map[Position]bool)
+
+    for i := 0; i < len(black); i++ {
+        if black[i].X >= board.BoardSize || black[i].Y >= board.BoardSize {
+            return fmt.Errorf("Invalid setup stone position!")
+        }
+        if board.getStatus(black[i].X, black[i].Y) != EMPTY || placed[black[i]] {
+            return fmt.Errorf("Position (%d, %d) is already occupied!", black[i].X, black[i].Y)
+        }
+        placed[black[i]] = true
+    }
+    for i := 0; i < len(white); i++ {
+        if white[i].X >= board.BoardSize || white[i].Y >= board.BoardSize {
+            return fmt.Errorf("Invalid setup stone position!")
+        }
+        if board.getStatus(white[i].X, white[i].Y) != EMPTY || placed[white[i]] {
+            return fmt.Errorf("Position (%d, %d) is already occupied!", white[i].X, white[i].Y)
+        }
+        placed[white[i]] = true
+    }
+
+    preHash := board.hash
+
+    for i := 0; i < len(black); i++ {
+        board.setStatus(black[i].X, black[i].Y, BLACK)
+    }
+    for i := 0; i < len(white); i++ {
+        board.setStatus(white[i].X, white[i].Y, WHITE)
+    }
+    board.rebuildStrings()
+
+    board.UndostackPush(&Move { 255, 255, SETUP, nil, preHash, black, white })
+
+    return nil
+}
@@ -0,0 +1,21 @@
+package libaduk
+
+// Represents a single entry on the Undostack: a stone placement together
+// with any stones it captured, a pass (Color == PASS), setup stones
+// (Color == SETUP) or stones removed as dead (Color == DEAD)
+type Move struct {
+    X        uint8
+    Y        uint8
+    Color    BoardStatus
+    Captures []Position
+
+    // Zobrist hash of the position before this move was played, so ko
+    // rules and UndostackPop can restore it without recomputing
+    PreHash uint64
+
+    // Only set when Color == SETUP (the stones placed by AddSetupStones) or
+    // Color == DEAD (the stones DeadStones removed, by their color), so
+    // UndostackPop can clear or restore exactly them
+    SetupBlack []Position
+    SetupWhite []Position
+}
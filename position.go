@@ -0,0 +1,12 @@
+package libaduk
+
+// Represents a single point on the board
+type Position struct {
+    X uint8
+    Y uint8
+}
+
+// Returns true if pos and other refer to the same point
+func (pos Position) isSamePosition(other Position) bool {
+    return pos.X == other.X && pos.Y == other.Y
+}
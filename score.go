@@ -0,0 +1,190 @@
+package libaduk
+
+// Selects which scoring method Score uses
+type ScoringRules int
+
+const (
+    // Area scoring: stones on the board plus surrounded territory
+    ChineseScoring ScoringRules = iota
+    // Territory scoring: surrounded territory plus prisoners
+    JapaneseScoring
+)
+
+// The result of scoring a finished (or in-progress) game
+type Score struct {
+    Black float32
+    White float32
+    Winner BoardStatus
+    Margin float32
+}
+
+// Returns true once two consecutive passes have been played. SETUP/DEAD
+// entries are bookkeeping rather than turns, so they're skipped over when
+// looking for the last two moves.
+func (board *AbstractBoard) IsGameOver() bool {
+    found := 0
+
+    for i := len(board.undoStack) - 1; i >= 0; i-- {
+        switch board.undoStack[i].Color {
+        case SETUP, DEAD:
+            continue
+        case PASS:
+            found++
+            if found == 2 {
+                return true
+            }
+        default:
+            return false
+        }
+    }
+
+    return false
+}
+
+// Removes the given stones from the board, treating them as dead before
+// scoring floods the resulting empty regions. Returns the stones actually
+// removed. Pushes a single DEAD entry onto the Undostack so UndostackPop
+// can restore exactly these stones again.
+func (board *AbstractBoard) DeadStones(dead []Position) []Position {
+    removed := []Position { }
+    removedBlack := []Position { }
+    removedWhite := []Position { }
+
+    preHash := board.hash
+
+    for i := 0; i < len(dead); i++ {
+        switch board.getStatus(dead[i].X, dead[i].Y) {
+        case BLACK:
+            removedBlack = append(removedBlack, dead[i])
+        case WHITE:
+            removedWhite = append(removedWhite, dead[i])
+        default:
+            continue
+        }
+
+        board.setStatus(dead[i].X, dead[i].Y, EMPTY)
+        removed = append(removed, dead[i])
+    }
+
+    board.rebuildStrings()
+
+    board.UndostackPush(&Move { 255, 255, DEAD, nil, preHash, removedBlack, removedWhite })
+
+    return removed
+}
+
+// Scores the current board position under the given rules
+func (board *AbstractBoard) Score(komi float32, rules ScoringRules) Score {
+    blackTerritory, whiteTerritory := board.floodTerritory()
+
+    var black, white float32
+
+    switch rules {
+    case JapaneseScoring:
+        blackPrisoners, whitePrisoners := board.countPrisoners()
+        black = float32(blackTerritory + blackPrisoners)
+        white = float32(whiteTerritory + whitePrisoners) + komi
+    default:
+        blackStones, whiteStones := board.countStones()
+        black = float32(blackStones + blackTerritory)
+        white = float32(whiteStones + whiteTerritory) + komi
+    }
+
+    score := Score { Black: black, White: white }
+
+    switch {
+    case black > white:
+        score.Winner = BLACK
+        score.Margin = black - white
+    case white > black:
+        score.Winner = WHITE
+        score.Margin = white - black
+    default:
+        score.Winner = EMPTY
+        score.Margin = 0
+    }
+
+    return score
+}
+
+// Flood fills every empty region, crediting it to whichever color alone
+// borders it; regions bordered by both colors (or neither) are dame
+func (board *AbstractBoard) floodTerritory() (blackTerritory int, whiteTerritory int) {
+    visited := make([]bool, len(board.data))
+
+    for idx := 0; idx < len(board.data); idx++ {
+        if visited[idx] || board.data[idx] != EMPTY {
+            continue
+        }
+
+        size := 0
+        bordersBlack := false
+        bordersWhite := false
+
+        queue := []int { idx }
+        visited[idx] = true
+
+        for len(queue) > 0 {
+            cur := queue[0]
+            queue = queue[1:]
+            size++
+
+            pos := board.indexToPosition(cur)
+            neighbours := board.getNeighbours(pos.X, pos.Y)
+            for i := 0; i < len(neighbours); i++ {
+                switch board.getStatus(neighbours[i].X, neighbours[i].Y) {
+                case EMPTY:
+                    nIdx := board.posIndex(neighbours[i].X, neighbours[i].Y)
+                    if !visited[nIdx] {
+                        visited[nIdx] = true
+                        queue = append(queue, nIdx)
+                    }
+                case BLACK:
+                    bordersBlack = true
+                case WHITE:
+                    bordersWhite = true
+                }
+            }
+        }
+
+        switch {
+        case bordersBlack && !bordersWhite:
+            blackTerritory += size
+        case bordersWhite && !bordersBlack:
+            whiteTerritory += size
+        }
+    }
+
+    return
+}
+
+func (board *AbstractBoard) countStones() (black int, white int) {
+    for i := 0; i < len(board.data); i++ {
+        switch board.data[i] {
+        case BLACK:
+            black++
+        case WHITE:
+            white++
+        }
+    }
+
+    return
+}
+
+func (board *AbstractBoard) countPrisoners() (black int, white int) {
+    for i := 0; i < len(board.undoStack); i++ {
+        switch board.undoStack[i].Color {
+        case BLACK:
+            black += len(board.undoStack[i].Captures)
+        case WHITE:
+            white += len(board.undoStack[i].Captures)
+        case DEAD:
+            // SetupBlack/SetupWhite carry the stones DeadStones removed, by
+            // their own color; each counts as a prisoner for the opponent
+            white += len(board.undoStack[i].SetupBlack)
+            black += len(board.undoStack[i].SetupWhite)
+        }
+    }
+
+    return
+}
@@ -0,0 +1,74 @@
+package libaduk
+
+import "testing"
+
+func TestIsGameOverSeesThroughDeadStones(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+
+    board.UndostackPushPass()
+    board.UndostackPushPass()
+
+    if !board.IsGameOver() {
+        t.Fatal("expected two consecutive passes to end the game")
+    }
+
+    board.DeadStones(nil)
+
+    if !board.IsGameOver() {
+        t.Fatal("marking dead stones should not un-end a game that already ended")
+    }
+}
+
+func TestDeadStonesIsUndoable(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+
+    if err := board.Play(4, 4, BLACK); err != nil {
+        t.Fatalf("Play(4, 4, BLACK): %v", err)
+    }
+
+    removed := board.DeadStones([]Position { { 4, 4 } })
+    if len(removed) != 1 {
+        t.Fatalf("expected 1 stone removed, got %d", len(removed))
+    }
+    if status := board.getStatus(4, 4); status != EMPTY {
+        t.Fatalf("expected (4,4) to be empty after DeadStones, got %v", status)
+    }
+
+    board.UndostackPop()
+
+    if status := board.getStatus(4, 4); status != BLACK {
+        t.Fatalf("expected undo to restore the dead black stone, got %v", status)
+    }
+}
+
+func TestScoreJapaneseCreditsDeadStonesAsPrisoners(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+
+    // A white stone at (4,4) fully enclosed by black, placed via setup so
+    // it isn't auto-captured - the "dead stone sitting in enemy territory"
+    // shape DeadStones exists to clean up at game end
+    black := []Position { { 3, 4 }, { 5, 4 }, { 4, 3 }, { 4, 5 } }
+    if err := board.AddSetupStones(black, []Position { { 4, 4 } }); err != nil {
+        t.Fatalf("AddSetupStones: %v", err)
+    }
+
+    before := board.Score(0, JapaneseScoring)
+    board.DeadStones([]Position { { 4, 4 } })
+    after := board.Score(0, JapaneseScoring)
+
+    // Removing the dead white stone hands its (now fully enclosed) point to
+    // black as territory *and* credits black with one prisoner, a 2-point
+    // swing in black's favor
+    if after.Black != before.Black + 2 {
+        t.Fatalf("expected DeadStones to add 2 to black's score (territory + prisoner), got %v -> %v", before.Black, after.Black)
+    }
+}
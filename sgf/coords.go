@@ -0,0 +1,73 @@
+package sgf
+
+import "fmt"
+
+// ParsePoint parses an SGF two-letter point value such as "pd" into board
+// coordinates
+func ParsePoint(value string) (x uint8, y uint8, err error) {
+    if len(value) != 2 {
+        return 0, 0, fmt.Errorf("SGF point %q must be exactly two characters!", value)
+    }
+
+    xi, err := sgfCoordToIndex(value[0])
+    if err != nil {
+        return 0, 0, err
+    }
+
+    yi, err := sgfCoordToIndex(value[1])
+    if err != nil {
+        return 0, 0, err
+    }
+
+    return uint8(xi), uint8(yi), nil
+}
+
+// FormatPoint formats board coordinates as an SGF two-letter point value
+func FormatPoint(x uint8, y uint8) (string, error) {
+    xc, err := indexToSGFCoord(int(x))
+    if err != nil {
+        return "", err
+    }
+
+    yc, err := indexToSGFCoord(int(y))
+    if err != nil {
+        return "", err
+    }
+
+    return string([]byte { xc, yc }), nil
+}
+
+// Point19 is a convenience helper for formatting a point on the common
+// 19x19 board
+func Point19(x uint8, y uint8) (string, error) {
+    if x > 18 || y > 18 {
+        return "", fmt.Errorf("Position (%d, %d) is outside a 19x19 board!", x, y)
+    }
+
+    return FormatPoint(x, y)
+}
+
+// sgfCoordToIndex maps a single SGF coordinate letter ('a'-'z', 'A'-'Z') to
+// a 0-based board index
+func sgfCoordToIndex(c byte) (int, error) {
+    switch {
+    case c >= 'a' && c <= 'z':
+        return int(c - 'a'), nil
+    case c >= 'A' && c <= 'Z':
+        return int(c-'A') + 26, nil
+    }
+
+    return 0, fmt.Errorf("Invalid SGF coordinate byte %q!", c)
+}
+
+// indexToSGFCoord maps a 0-based board index to a single SGF coordinate letter
+func indexToSGFCoord(i int) (byte, error) {
+    switch {
+    case i >= 0 && i < 26:
+        return byte('a' + i), nil
+    case i >= 26 && i < MaxBoardSize:
+        return byte('A' + (i - 26)), nil
+    }
+
+    return 0, fmt.Errorf("Board coordinate %d is outside SGF's 0..%d range!", i, MaxBoardSize - 1)
+}
@@ -0,0 +1,233 @@
+package sgf
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "strings"
+)
+
+// Parse reads a single SGF (FF[4]) game tree from r. SGF allows variations,
+// so the result is a tree of Nodes rather than a flat list of moves.
+func Parse(r io.Reader) (*GameTree, error) {
+    p := &parser { reader: bufio.NewReader(r) }
+
+    root, err := p.parseGameTree()
+    if err != nil {
+        return nil, err
+    }
+
+    return &GameTree { Root: root }, nil
+}
+
+type parser struct {
+    reader *bufio.Reader
+}
+
+func (p *parser) peek() (rune, error) {
+    r, _, err := p.reader.ReadRune()
+    if err != nil {
+        return 0, err
+    }
+
+    p.reader.UnreadRune()
+    return r, nil
+}
+
+func (p *parser) skipWhitespace() error {
+    for {
+        r, _, err := p.reader.ReadRune()
+        if err != nil {
+            return err
+        }
+        if !isSpace(r) {
+            p.reader.UnreadRune()
+            return nil
+        }
+    }
+}
+
+func isSpace(r rune) bool {
+    return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+func isUpper(r rune) bool {
+    return r >= 'A' && r <= 'Z'
+}
+
+// parseGameTree parses "(" sequence { gametree } ")". The sequence becomes
+// a chain of Children; any nested gametrees are attached as Children of
+// the sequence's last node, i.e. as variations at that branch point.
+func (p *parser) parseGameTree() (*Node, error) {
+    if err := p.skipWhitespace(); err != nil {
+        return nil, fmt.Errorf("Unexpected end of SGF while looking for '('!")
+    }
+
+    r, _, err := p.reader.ReadRune()
+    if err != nil {
+        return nil, fmt.Errorf("Unexpected end of SGF while looking for '('!")
+    }
+    if r != '(' {
+        return nil, fmt.Errorf("Expected '(' to start a GameTree, got %q!", r)
+    }
+
+    root, last, err := p.parseSequence()
+    if err != nil {
+        return nil, err
+    }
+
+    for {
+        if err := p.skipWhitespace(); err != nil {
+            return nil, fmt.Errorf("Unexpected end of SGF while looking for ')'!")
+        }
+
+        next, err := p.peek()
+        if err != nil {
+            return nil, fmt.Errorf("Unexpected end of SGF while looking for ')'!")
+        }
+
+        if next == ')' {
+            p.reader.ReadRune()
+            break
+        }
+
+        variation, err := p.parseGameTree()
+        if err != nil {
+            return nil, err
+        }
+        last.Children = append(last.Children, variation)
+    }
+
+    return root, nil
+}
+
+// parseSequence parses one or more ";node" entries, chaining them as a
+// straight line of Children, and returns both the first and last node
+func (p *parser) parseSequence() (first *Node, last *Node, err error) {
+    for {
+        if err = p.skipWhitespace(); err != nil {
+            return nil, nil, fmt.Errorf("Unexpected end of SGF inside a sequence!")
+        }
+
+        r, peekErr := p.peek()
+        if peekErr != nil || r != ';' {
+            if first == nil {
+                return nil, nil, fmt.Errorf("Expected at least one node (starting with ';') in sequence!")
+            }
+            return first, last, nil
+        }
+        p.reader.ReadRune()
+
+        node, err := p.parseNode()
+        if err != nil {
+            return nil, nil, err
+        }
+
+        if first == nil {
+            first = node
+        } else {
+            last.Children = append(last.Children, node)
+        }
+        last = node
+    }
+}
+
+// parseNode parses the properties following a ';'
+func (p *parser) parseNode() (*Node, error) {
+    node := newNode()
+
+    for {
+        if err := p.skipWhitespace(); err != nil {
+            return node, nil
+        }
+
+        r, err := p.peek()
+        if err != nil || !isUpper(r) {
+            return node, nil
+        }
+
+        ident, err := p.parsePropIdent()
+        if err != nil {
+            return nil, err
+        }
+
+        values, err := p.parsePropValues()
+        if err != nil {
+            return nil, err
+        }
+
+        node.Properties[ident] = append(node.Properties[ident], values...)
+    }
+}
+
+func (p *parser) parsePropIdent() (string, error) {
+    var ident strings.Builder
+
+    for {
+        r, _, err := p.reader.ReadRune()
+        if err != nil {
+            return "", fmt.Errorf("Unexpected end of SGF while reading a property identifier!")
+        }
+        if !isUpper(r) {
+            p.reader.UnreadRune()
+            break
+        }
+        ident.WriteRune(r)
+    }
+
+    return ident.String(), nil
+}
+
+func (p *parser) parsePropValues() ([]string, error) {
+    values := []string { }
+
+    for {
+        if err := p.skipWhitespace(); err != nil {
+            return nil, fmt.Errorf("Unexpected end of SGF while reading property values!")
+        }
+
+        r, err := p.peek()
+        if err != nil || r != '[' {
+            break
+        }
+        p.reader.ReadRune()
+
+        value, err := p.parsePropValue()
+        if err != nil {
+            return nil, err
+        }
+        values = append(values, value)
+    }
+
+    if len(values) == 0 {
+        return nil, fmt.Errorf("Property is missing its value!")
+    }
+
+    return values, nil
+}
+
+func (p *parser) parsePropValue() (string, error) {
+    var value strings.Builder
+
+    for {
+        r, _, err := p.reader.ReadRune()
+        if err != nil {
+            return "", fmt.Errorf("Unexpected end of SGF while reading a property value!")
+        }
+
+        if r == '\\' {
+            escaped, _, err := p.reader.ReadRune()
+            if err != nil {
+                return "", fmt.Errorf("Unexpected end of SGF after an escape character!")
+            }
+            value.WriteRune(escaped)
+            continue
+        }
+
+        if r == ']' {
+            return value.String(), nil
+        }
+
+        value.WriteRune(r)
+    }
+}
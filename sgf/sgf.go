@@ -0,0 +1,227 @@
+// Package sgf parses and emits Smart Game Format (FF[4]) game records and
+// binds them to libaduk's AbstractBoard.
+package sgf
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+
+    aduk "github.com/arjenketelaar/libaduk"
+)
+
+// SGF's letter-based coordinates only cover boards up to 52x52
+const (
+    MinBoardSize = 1
+    MaxBoardSize = 52
+)
+
+// Represents a single node of an SGF game tree, carrying zero or more
+// properties (e.g. "B" -> ["pd"]) and zero or more child variations. A
+// node with more than one child is a branch point; the first child is
+// always the mainline continuation.
+type Node struct {
+    Properties map[string][]string
+    Children []*Node
+}
+
+// Represents a parsed (or constructed) SGF game tree
+type GameTree struct {
+    Root *Node
+}
+
+func newNode() *Node {
+    return &Node {
+        Properties: make(map[string][]string),
+        Children: []*Node { },
+    }
+}
+
+// Replay walks the mainline of the tree, applying setup stones and moves
+// to board via PlayMove
+func (tree *GameTree) Replay(board *aduk.AbstractBoard) error {
+    if sz := tree.Root.Properties["SZ"]; len(sz) > 0 {
+        size, err := strconv.Atoi(sz[0])
+        if err != nil {
+            return fmt.Errorf("Invalid SZ property %q!", sz[0])
+        }
+        if size < MinBoardSize || size > MaxBoardSize {
+            return fmt.Errorf("Board size %d is outside the supported %d..%d range!", size, MinBoardSize, MaxBoardSize)
+        }
+        if uint8(size) != board.BoardSize {
+            return fmt.Errorf("SGF board size %d does not match board size %d!", size, board.BoardSize)
+        }
+    }
+
+    for node := tree.Root; node != nil; {
+        if err := applyNode(board, node); err != nil {
+            return err
+        }
+
+        if len(node.Children) == 0 {
+            break
+        }
+        node = node.Children[0]
+    }
+
+    return nil
+}
+
+// applyNode plays the setup stones and move carried by a single node
+func applyNode(board *aduk.AbstractBoard, node *Node) error {
+    for _, value := range node.Properties["AB"] {
+        if err := playPoint(board, value, aduk.BLACK); err != nil {
+            return err
+        }
+    }
+    for _, value := range node.Properties["AW"] {
+        if err := playPoint(board, value, aduk.WHITE); err != nil {
+            return err
+        }
+    }
+
+    for _, value := range node.Properties["B"] {
+        if err := playMoveOrPass(board, value, aduk.BLACK); err != nil {
+            return err
+        }
+    }
+    for _, value := range node.Properties["W"] {
+        if err := playMoveOrPass(board, value, aduk.WHITE); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+func playPoint(board *aduk.AbstractBoard, value string, color aduk.BoardStatus) error {
+    x, y, err := ParsePoint(value)
+    if err != nil {
+        return err
+    }
+
+    return board.Play(x, y, color)
+}
+
+func playMoveOrPass(board *aduk.AbstractBoard, value string, color aduk.BoardStatus) error {
+    if value == "" {
+        board.UndostackPushPass()
+        return nil
+    }
+
+    return playPoint(board, value, color)
+}
+
+// formatPoints formats each position as an SGF point, skipping any that
+// fail to format (e.g. outside the supported coordinate range)
+func formatPoints(positions []aduk.Position) []string {
+    points := []string { }
+
+    for i := 0; i < len(positions); i++ {
+        point, err := FormatPoint(positions[i].X, positions[i].Y)
+        if err == nil {
+            points = append(points, point)
+        }
+    }
+
+    return points
+}
+
+// NewGameTreeFromBoard reconstructs an SGF tree from the moves played on
+// board so far
+func NewGameTreeFromBoard(board *aduk.AbstractBoard) *GameTree {
+    root := newNode()
+    root.Properties["FF"] = []string { "4" }
+    root.Properties["GM"] = []string { "1" }
+    root.Properties["SZ"] = []string { strconv.Itoa(int(board.BoardSize)) }
+
+    current := root
+    for i, move := range board.Moves() {
+        // DEAD entries record stones DeadStones removed for scoring, not a
+        // move that was played; they have no place in the SGF record
+        if move.Color == aduk.DEAD {
+            continue
+        }
+
+        node := newNode()
+
+        if move.Color == aduk.SETUP {
+            if points := formatPoints(move.SetupBlack); len(points) > 0 {
+                node.Properties["AB"] = points
+            }
+            if points := formatPoints(move.SetupWhite); len(points) > 0 {
+                node.Properties["AW"] = points
+            }
+        } else {
+            tag := "B"
+            if (move.Color == aduk.WHITE) || (move.Color == aduk.PASS && i % 2 != 0) {
+                tag = "W"
+            }
+
+            value := ""
+            if move.Color != aduk.PASS {
+                point, err := FormatPoint(move.X, move.Y)
+                if err == nil {
+                    value = point
+                }
+            }
+            node.Properties[tag] = []string { value }
+        }
+
+        current.Children = append(current.Children, node)
+        current = node
+    }
+
+    return &GameTree { Root: root }
+}
+
+// Write serializes the tree as "(;FF[4]GM[1]SZ[...]...;B[pd];W[dp]...)"
+func (tree *GameTree) Write(w io.Writer) error {
+    var b strings.Builder
+    b.WriteString("(")
+    writeNode(&b, tree.Root)
+    b.WriteString(")")
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+func writeNode(b *strings.Builder, node *Node) {
+    b.WriteString(";")
+
+    keys := make([]string, 0, len(node.Properties))
+    for key := range node.Properties {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    for _, key := range keys {
+        b.WriteString(key)
+        for _, value := range node.Properties[key] {
+            b.WriteString("[")
+            b.WriteString(escapeValue(value))
+            b.WriteString("]")
+        }
+    }
+
+    switch len(node.Children) {
+    case 0:
+        return
+    case 1:
+        writeNode(b, node.Children[0])
+    default:
+        for _, child := range node.Children {
+            b.WriteString("(")
+            writeNode(b, child)
+            b.WriteString(")")
+        }
+    }
+}
+
+func escapeValue(value string) string {
+    value = strings.ReplaceAll(value, "\\", "\\\\")
+    value = strings.ReplaceAll(value, "]", "\\]")
+    return value
+}
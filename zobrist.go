@@ -0,0 +1,90 @@
+package libaduk
+
+import (
+    "fmt"
+    "math/rand"
+)
+
+// Fixed seed so the Zobrist table (and therefore board hashes) is
+// reproducible across processes
+const zobristSeed = 0x5A5AC0DEF00DBEEF
+
+// Selects which ko/superko rule Play enforces
+type KoRule int
+
+const (
+    // No ko enforcement at all
+    KoNone KoRule = iota
+    // Classic single-move ko: forbids immediately recapturing the stone
+    // that was just captured, restoring the position from 2 plies ago
+    KoSimple
+    // Forbids recreating any previous whole-board position, regardless of
+    // whose turn it is
+    KoPositionalSuperko
+    // Like KoPositionalSuperko, but the side to move is mixed into the
+    // hash so the same stones with the other side to move are allowed
+    KoSituationalSuperko
+)
+
+// SetKoRule selects the ko rule enforced by Play
+func (board *AbstractBoard) SetKoRule(rule KoRule) {
+    board.koRule = rule
+}
+
+// (Re)generates the Zobrist table and clears the position history
+func (board *AbstractBoard) resetZobrist() {
+    size := len(board.data)
+    rng := rand.New(rand.NewSource(zobristSeed))
+
+    board.zobrist[0] = make([]uint64, size)
+    board.zobrist[1] = make([]uint64, size)
+    for i := 0; i < size; i++ {
+        board.zobrist[0][i] = rng.Uint64()
+        board.zobrist[1][i] = rng.Uint64()
+    }
+
+    board.zobristSide[0] = rng.Uint64()
+    board.zobristSide[1] = rng.Uint64()
+
+    board.hash = 0
+    board.seen = map[uint64]struct{} { 0: {} }
+}
+
+func (board *AbstractBoard) zobristColorIndex(color BoardStatus) int {
+    if color == WHITE {
+        return 1
+    }
+
+    return 0
+}
+
+// checkKo enforces the active ko rule for the move that was just tentatively
+// played by color, capturing captures (board.hash is already the position
+// after that move). Under the superko rules it registers the new position in
+// the history set so later moves are checked against it.
+func (board *AbstractBoard) checkKo(color BoardStatus, captures []Position) error {
+    switch board.koRule {
+    case KoSimple:
+        // Forbidden if we recreated the position from 2 plies ago, i.e.
+        // the hash recorded just before the previous move was played
+        if len(board.undoStack) > 0 {
+            previous := board.undoStack[len(board.undoStack) - 1]
+            if len(captures) > 0 && board.hash == previous.PreHash {
+                return fmt.Errorf("Invalid move (Ko violation)!")
+            }
+        }
+
+    case KoPositionalSuperko, KoSituationalSuperko:
+        hash := board.hash
+        if board.koRule == KoSituationalSuperko {
+            hash ^= board.zobristSide[board.zobristColorIndex(board.invertColor(color))]
+        }
+
+        if _, seen := board.seen[hash]; seen {
+            return fmt.Errorf("Invalid move (Superko violation)!")
+        }
+        board.seen[hash] = struct{} { }
+    }
+
+    return nil
+}
@@ -0,0 +1,80 @@
+package libaduk
+
+import "testing"
+
+// koCapture sets up the smallest classic ko shape and plays the capturing
+// move, leaving a single Black stone at (1,2) in atari with its only
+// liberty at (1,1) - the point White would need to recapture at to
+// reproduce the pre-capture position.
+//
+//   . B .
+//   B W B
+//   W . W
+//   . W .
+func koCapture(t *testing.T, board *AbstractBoard) {
+    t.Helper()
+
+    plays := []struct { x, y uint8; color BoardStatus } {
+        { 1, 1, WHITE },
+        { 0, 2, WHITE },
+        { 2, 2, WHITE },
+        { 1, 3, WHITE },
+        { 1, 0, BLACK },
+        { 0, 1, BLACK },
+        { 2, 1, BLACK },
+        { 1, 2, BLACK },
+    }
+
+    for _, p := range plays {
+        if err := board.Play(p.x, p.y, p.color); err != nil {
+            t.Fatalf("Play(%d, %d, %v): %v", p.x, p.y, p.color, err)
+        }
+    }
+
+    if status := board.getStatus(1, 1); status != EMPTY {
+        t.Fatalf("expected the ko shape's capture to leave (1,1) empty, got %v", status)
+    }
+}
+
+func TestCheckKoRejectsSimpleKoRecapture(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+    board.SetKoRule(KoSimple)
+    koCapture(t, board)
+
+    if err := board.Play(1, 1, WHITE); err == nil {
+        t.Fatal("expected immediate ko recapture to be rejected")
+    }
+}
+
+func TestCheckKoPositionalSuperkoRejectsRepeatedPosition(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+    board.SetKoRule(KoPositionalSuperko)
+    koCapture(t, board)
+
+    if err := board.Play(1, 1, WHITE); err == nil {
+        t.Fatal("expected recreating the pre-capture position to be rejected")
+    }
+}
+
+func TestCheckKoSituationalSuperkoAllowsReplayAfterUndo(t *testing.T) {
+    board, err := NewBoard(9)
+    if err != nil {
+        t.Fatalf("NewBoard: %v", err)
+    }
+    board.SetKoRule(KoSituationalSuperko)
+
+    if err := board.Play(4, 4, BLACK); err != nil {
+        t.Fatalf("Play(4, 4, BLACK): %v", err)
+    }
+    board.UndostackPop()
+
+    if err := board.Play(4, 4, BLACK); err != nil {
+        t.Fatalf("replaying (4, 4, BLACK) after undo should be legal, got: %v", err)
+    }
+}